@@ -0,0 +1,52 @@
+// Copyright 2017 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blueprint
+
+// resolveAliasChain follows aliases starting at name until it reaches a name that is
+// not itself an alias, and returns that name. A cycle (which AddAlias and Rename
+// should never allow to be created) is treated as a dead end, returning the first
+// name seen twice rather than looping forever.
+func resolveAliasChain(aliases map[string]string, name string) string {
+	seen := make(map[string]bool)
+	for {
+		target, isAlias := aliases[name]
+		if !isAlias || seen[name] {
+			return name
+		}
+		seen[name] = true
+		name = target
+	}
+}
+
+// aliasChainCreatesCycle reports whether adding an alias from alias to target would
+// create a cycle, given the alias chains already recorded in aliases.
+func aliasChainCreatesCycle(aliases map[string]string, alias string, target string) bool {
+	seen := make(map[string]bool)
+	cur := target
+	for {
+		if cur == alias {
+			return true
+		}
+		if seen[cur] {
+			return false
+		}
+		seen[cur] = true
+		next, isAlias := aliases[cur]
+		if !isAlias {
+			return false
+		}
+		cur = next
+	}
+}
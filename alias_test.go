@@ -0,0 +1,121 @@
+// Copyright 2017 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blueprint
+
+import "testing"
+
+func TestSimpleNameInterface_AliasResolves(t *testing.T) {
+	s := NewSimpleNameInterface()
+	if _, errs := s.NewModule(testCtx("Blueprints"), newTestModuleGroup("foo"), nil); len(errs) != 0 {
+		t.Fatalf("NewModule(foo): %v", errs)
+	}
+	if errs := s.AddAlias("bar", "foo", nil); errs != nil {
+		t.Fatalf("AddAlias(bar, foo): %v", errs)
+	}
+
+	group, found := s.ModuleFromName("bar", nil)
+	if !found || group.name != "foo" {
+		t.Fatalf("ModuleFromName(bar) = %v, %v; want foo", group, found)
+	}
+}
+
+func TestSimpleNameInterface_RenameLeavesAliasChain(t *testing.T) {
+	s := NewSimpleNameInterface()
+	if _, errs := s.NewModule(testCtx("Blueprints"), newTestModuleGroup("v1"), nil); len(errs) != 0 {
+		t.Fatalf("NewModule(v1): %v", errs)
+	}
+	if errs := s.Rename("v1", "v2", nil); errs != nil {
+		t.Fatalf("Rename(v1, v2): %v", errs)
+	}
+	if errs := s.Rename("v2", "v3", nil); errs != nil {
+		t.Fatalf("Rename(v2, v3): %v", errs)
+	}
+
+	for _, name := range []string{"v1", "v2", "v3"} {
+		group, found := s.ModuleFromName(name, nil)
+		if !found || group.name != "v3" {
+			t.Errorf("ModuleFromName(%s) = %v, %v; want v3", name, group, found)
+		}
+	}
+}
+
+func TestSimpleNameInterface_RenameWithoutAlias(t *testing.T) {
+	s := NewSimpleNameInterface()
+	if _, errs := s.NewModule(testCtx("Blueprints"), newTestModuleGroup("v1"), nil); len(errs) != 0 {
+		t.Fatalf("NewModule(v1): %v", errs)
+	}
+	if errs := s.RenameWithoutAlias("v1", "v2", nil); errs != nil {
+		t.Fatalf("RenameWithoutAlias(v1, v2): %v", errs)
+	}
+
+	if _, found := s.ModuleFromName("v1", nil); found {
+		t.Errorf("ModuleFromName(v1) unexpectedly found a module after RenameWithoutAlias")
+	}
+	if _, found := s.ModuleFromName("v2", nil); !found {
+		t.Errorf("ModuleFromName(v2) not found after RenameWithoutAlias")
+	}
+}
+
+func TestSimpleNameInterface_AddAliasCycleRejected(t *testing.T) {
+	s := NewSimpleNameInterface()
+	if errs := s.AddAlias("a", "b", nil); errs != nil {
+		t.Fatalf("AddAlias(a, b): %v", errs)
+	}
+	if errs := s.AddAlias("b", "a", nil); errs == nil {
+		t.Fatalf("AddAlias(b, a) succeeded; want a cycle error")
+	}
+}
+
+func TestSimpleNameInterface_AddAliasConflictsWithModule(t *testing.T) {
+	s := NewSimpleNameInterface()
+	if _, errs := s.NewModule(testCtx("Blueprints"), newTestModuleGroup("foo"), nil); len(errs) != 0 {
+		t.Fatalf("NewModule(foo): %v", errs)
+	}
+	if errs := s.AddAlias("foo", "bar", nil); errs == nil {
+		t.Fatalf("AddAlias(foo, bar) succeeded; want a conflict error since foo is already a module")
+	}
+}
+
+func TestSimpleNameInterface_RenameBackRejected(t *testing.T) {
+	s := NewSimpleNameInterface()
+	if _, errs := s.NewModule(testCtx("Blueprints"), newTestModuleGroup("m1"), nil); len(errs) != 0 {
+		t.Fatalf("NewModule(m1): %v", errs)
+	}
+	if errs := s.Rename("m1", "m2", nil); errs != nil {
+		t.Fatalf("Rename(m1, m2): %v", errs)
+	}
+	if errs := s.Rename("m2", "m1", nil); errs == nil {
+		t.Fatalf("Rename(m2, m1) succeeded; want it rejected since m1 is already an alias to m2")
+	}
+}
+
+func TestNamespaceNameInterface_AliasResolves(t *testing.T) {
+	r := NewNamespaceNameInterface()
+	if errs := r.NewNamespace("a"); errs != nil {
+		t.Fatalf("NewNamespace(a): %v", errs)
+	}
+	ns, errs := r.NewModule(testCtx("a/Blueprints"), newTestModuleGroup("foo"), nil)
+	if len(errs) != 0 {
+		t.Fatalf("NewModule(a/foo): %v", errs)
+	}
+	if errs := r.AddAlias("bar", "foo", ns); errs != nil {
+		t.Fatalf("AddAlias(bar, foo): %v", errs)
+	}
+
+	group, found := r.ModuleFromName("bar", ns)
+	if !found || group.name != "foo" {
+		t.Fatalf("ModuleFromName(bar) = %v, %v; want foo", group, found)
+	}
+}
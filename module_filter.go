@@ -0,0 +1,61 @@
+// Copyright 2017 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blueprint
+
+import "strings"
+
+// A ModuleFilter decides whether a module defined at the given module path should be
+// registered. Modules it rejects are routed to NewSkippedModule instead of being
+// registered, the same as modules pruned by an external SourceRootDirs check.
+type ModuleFilter func(modulePath string) bool
+
+// SourceRootDirs is an ordered list of allowed source directory prefixes. A prefix
+// beginning with "-" excludes that subtree from an allowed root (e.g. "-vendor/foo"
+// excludes vendor/foo from an otherwise-allowed "vendor" root). When more than one
+// entry matches a path, the longest matching prefix wins, wherever the entries appear
+// in the list.
+type SourceRootDirs []string
+
+// Allows reports whether path lies under one of the allowed roots, honoring negated
+// entries.
+func (d SourceRootDirs) Allows(path string) bool {
+	allowed := false
+	longestMatch := -1
+	for _, root := range d {
+		prefix := strings.TrimPrefix(root, "-")
+		if !isUnderDir(path, prefix) {
+			continue
+		}
+		if len(prefix) > longestMatch {
+			longestMatch = len(prefix)
+			allowed = prefix == root // false if root had a "-" prefix
+		}
+	}
+	return allowed
+}
+
+// ModuleFilter returns a ModuleFilter that allows exactly the paths d.Allows allows.
+func (d SourceRootDirs) ModuleFilter() ModuleFilter {
+	return d.Allows
+}
+
+// isUnderDir reports whether path is dir itself or lies under it. An empty dir
+// matches every path.
+func isUnderDir(path, dir string) bool {
+	if dir == "" || path == dir {
+		return true
+	}
+	return strings.HasPrefix(path, dir+"/")
+}
@@ -0,0 +1,117 @@
+// Copyright 2017 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blueprint
+
+import "testing"
+
+func TestSourceRootDirs_Allows(t *testing.T) {
+	dirs := SourceRootDirs{"frameworks", "device"}
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"frameworks", true},
+		{"frameworks/base", true},
+		{"device/generic", true},
+		{"vendor/google", false},
+		{"frameworks-extra", false}, // must not match on a bare string prefix
+	}
+	for _, tt := range tests {
+		if got := dirs.Allows(tt.path); got != tt.want {
+			t.Errorf("Allows(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestSourceRootDirs_NestedExclude(t *testing.T) {
+	dirs := SourceRootDirs{"vendor", "-vendor/foo"}
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"vendor/bar", true},
+		{"vendor/foo", false},
+		{"vendor/foo/baz", false},
+	}
+	for _, tt := range tests {
+		if got := dirs.Allows(tt.path); got != tt.want {
+			t.Errorf("Allows(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestSourceRootDirs_LongestMatchWins(t *testing.T) {
+	// The order of entries must not matter: the longest matching prefix always
+	// decides, whether the broader include or the narrower exclude comes first.
+	forward := SourceRootDirs{"vendor", "-vendor/foo"}
+	backward := SourceRootDirs{"-vendor/foo", "vendor"}
+	for _, dirs := range []SourceRootDirs{forward, backward} {
+		if dirs.Allows("vendor/foo") {
+			t.Errorf("%v.Allows(vendor/foo) = true, want false", dirs)
+		}
+		if !dirs.Allows("vendor/bar") {
+			t.Errorf("%v.Allows(vendor/bar) = false, want true", dirs)
+		}
+	}
+}
+
+func TestSimpleNameInterface_ModuleFilter_SkipsOutsideRoots(t *testing.T) {
+	filter := SourceRootDirs{"frameworks"}.ModuleFilter()
+	s := NewSimpleNameInterfaceWithFilter(filter)
+
+	if _, errs := s.NewModule(testCtx("vendor/foo/Blueprints"), newTestModuleGroup("foo"), nil); len(errs) != 0 {
+		t.Fatalf("NewModule outside source roots returned errors: %v", errs)
+	}
+
+	if _, found := s.ModuleFromName("foo", nil); found {
+		t.Errorf("module outside the allowed source roots was registered")
+	}
+	if _, skipped := s.SkippedModuleFromName("foo", nil); !skipped {
+		t.Errorf("module outside the allowed source roots was not recorded as skipped")
+	}
+}
+
+func TestSimpleNameInterface_ModuleFilter_DuplicateInsideAndOutside(t *testing.T) {
+	filter := SourceRootDirs{"frameworks"}.ModuleFilter()
+	s := NewSimpleNameInterfaceWithFilter(filter)
+
+	if _, errs := s.NewModule(testCtx("vendor/foo/Blueprints"), newTestModuleGroup("foo"), nil); len(errs) != 0 {
+		t.Fatalf("NewModule outside source roots: %v", errs)
+	}
+	if _, errs := s.NewModule(testCtx("frameworks/foo/Blueprints"), newTestModuleGroup("foo"), nil); len(errs) != 0 {
+		t.Fatalf("NewModule inside source roots returned errors: %v", errs)
+	}
+
+	group, found := s.ModuleFromName("foo", nil)
+	if !found || group.name != "foo" {
+		t.Fatalf("ModuleFromName(foo) = %v, %v; want the in-root definition", group, found)
+	}
+}
+
+func TestNamespaceNameInterface_ModuleFilter_SkipsOutsideRoots(t *testing.T) {
+	filter := SourceRootDirs{"frameworks"}.ModuleFilter()
+	r := NewNamespaceNameInterfaceWithFilter(filter)
+
+	if _, errs := r.NewModule(testCtx("vendor/foo/Blueprints"), newTestModuleGroup("foo"), nil); len(errs) != 0 {
+		t.Fatalf("NewModule outside source roots returned errors: %v", errs)
+	}
+
+	if _, found := r.ModuleFromName("foo", nil); found {
+		t.Errorf("module outside the allowed source roots was registered")
+	}
+	if _, skipped := r.SkippedModuleFromName("foo", nil); !skipped {
+		t.Errorf("module outside the allowed source roots was not recorded as skipped")
+	}
+}
@@ -71,6 +71,11 @@ type NameInterface interface {
 	// Rename
 	Rename(oldName string, newName string, namespace Namespace) []error
 
+	// AddAlias makes alias resolve to target when passed to ModuleFromName, without
+	// alias appearing as a module of its own in AllModules. Returns an error if alias
+	// conflicts with an existing module or alias, or if it would introduce a cycle.
+	AddAlias(alias string, target string, namespace Namespace) []error
+
 	// Returns all modules in a deterministic order.
 	AllModules() []ModuleGroup
 
@@ -112,17 +117,53 @@ type SkippedModuleInfo struct {
 type SimpleNameInterface struct {
 	modules        map[string]ModuleGroup
 	skippedModules map[string][]SkippedModuleInfo
+	aliases        map[string]string
+
+	// SanitizeUniqueNames, if true, makes UniqueName map names to ninja-safe
+	// identifiers instead of returning them unchanged. See
+	// NewSimpleNameInterfaceWithSanitize.
+	SanitizeUniqueNames bool
+	uniqueNames         map[string]string
+	ninjaBaseClaims     map[string]int
+
+	// ModuleFilter, if set, is consulted by NewModule to decide whether a module
+	// should be registered at all. Modules it rejects are recorded as skipped
+	// instead. See NewSimpleNameInterfaceWithFilter.
+	ModuleFilter ModuleFilter
 }
 
 func NewSimpleNameInterface() *SimpleNameInterface {
 	return &SimpleNameInterface{
-		modules:        make(map[string]ModuleGroup),
-		skippedModules: make(map[string][]SkippedModuleInfo),
+		modules:         make(map[string]ModuleGroup),
+		skippedModules:  make(map[string][]SkippedModuleInfo),
+		aliases:         make(map[string]string),
+		uniqueNames:     make(map[string]string),
+		ninjaBaseClaims: make(map[string]int),
 	}
 }
 
+// NewSimpleNameInterfaceWithFilter is like NewSimpleNameInterface, but the returned
+// SimpleNameInterface's NewModule routes any module whose path filter rejects to
+// NewSkippedModule instead of registering it. This allows the same module name to be
+// defined once inside and once outside the allowed source roots without the outside
+// definition triggering a duplicate-module error.
+func NewSimpleNameInterfaceWithFilter(filter ModuleFilter) *SimpleNameInterface {
+	s := NewSimpleNameInterface()
+	s.ModuleFilter = filter
+	return s
+}
+
 func (s *SimpleNameInterface) NewModule(ctx NamespaceContext, group ModuleGroup, module Module) (namespace Namespace, err []error) {
 	name := group.name
+
+	if s.ModuleFilter != nil && !s.ModuleFilter(ctx.ModulePath()) {
+		s.NewSkippedModule(ctx, name, SkippedModuleInfo{
+			filename: ctx.ModulePath(),
+			reason:   fmt.Sprintf("path %q not under any allowed source root", ctx.ModulePath()),
+		})
+		return nil, nil
+	}
+
 	if group, present := s.modules[name]; present {
 		return nil, []error{
 			// seven characters at the start of the second line to align with the string "error: "
@@ -130,6 +171,9 @@ func (s *SimpleNameInterface) NewModule(ctx NamespaceContext, group ModuleGroup,
 				"       %s <-- previous definition here", name, group.modules.firstModule().pos),
 		}
 	}
+	if _, present := s.aliases[name]; present {
+		return nil, []error{fmt.Errorf("module %q already defined as an alias for %q", name, s.aliases[name])}
+	}
 
 	s.modules[name] = group
 
@@ -144,7 +188,7 @@ func (s *SimpleNameInterface) NewSkippedModule(ctx NamespaceContext, name string
 }
 
 func (s *SimpleNameInterface) ModuleFromName(moduleName string, namespace Namespace) (group ModuleGroup, found bool) {
-	group, found = s.modules[moduleName]
+	group, found = s.modules[resolveAliasChain(s.aliases, moduleName)]
 	return group, found
 }
 
@@ -153,9 +197,23 @@ func (s *SimpleNameInterface) SkippedModuleFromName(moduleName string, namespace
 	return
 }
 
+// Rename renames oldName to newName, then leaves oldName behind as an alias to
+// newName so that dependencies declared against the pre-rename name keep resolving.
+// This is the common Soong pattern of a mutator introducing variant naming (e.g.
+// version suffixes) without breaking existing callers. Use RenameWithoutAlias to
+// rename without leaving an alias behind.
 func (s *SimpleNameInterface) Rename(oldName string, newName string, namespace Namespace) (errs []error) {
-	existingGroup, exists := s.modules[newName]
-	if exists {
+	return s.rename(oldName, newName, true)
+}
+
+// RenameWithoutAlias behaves like Rename, but does not leave oldName resolvable as an
+// alias to newName.
+func (s *SimpleNameInterface) RenameWithoutAlias(oldName string, newName string, namespace Namespace) (errs []error) {
+	return s.rename(oldName, newName, false)
+}
+
+func (s *SimpleNameInterface) rename(oldName string, newName string, keepAlias bool) (errs []error) {
+	if existingGroup, exists := s.modules[newName]; exists {
 		return []error{
 			// seven characters at the start of the second line to align with the string "error: "
 			fmt.Errorf("renaming module %q to %q conflicts with existing module\n"+
@@ -163,14 +221,48 @@ func (s *SimpleNameInterface) Rename(oldName string, newName string, namespace N
 				oldName, newName, existingGroup.modules.firstModule().pos),
 		}
 	}
+	if target, exists := s.aliases[newName]; exists {
+		return []error{fmt.Errorf("renaming module %q to %q conflicts with existing alias to %q", oldName, newName, target)}
+	}
 
 	group, exists := s.modules[oldName]
 	if !exists {
 		return []error{fmt.Errorf("module %q to renamed to %q doesn't exist", oldName, newName)}
 	}
-	s.modules[newName] = group
+
+	if keepAlias && aliasChainCreatesCycle(s.aliases, oldName, newName) {
+		return []error{fmt.Errorf("renaming module %q to %q would create an alias cycle", oldName, newName)}
+	}
+
 	delete(s.modules, group.name)
 	group.name = newName
+	s.modules[newName] = group
+	if keepAlias {
+		s.aliases[oldName] = newName
+	} else {
+		delete(s.aliases, oldName)
+	}
+	return nil
+}
+
+// AddAlias makes alias resolve to target when passed to ModuleFromName. alias must
+// not already name a module or another alias, and the resulting alias chain must not
+// contain a cycle.
+func (s *SimpleNameInterface) AddAlias(alias string, target string, namespace Namespace) (errs []error) {
+	if group, exists := s.modules[alias]; exists {
+		return []error{
+			fmt.Errorf("alias %q conflicts with existing module\n"+
+				"       %s <-- existing module defined here", alias, group.modules.firstModule().pos),
+		}
+	}
+	if existingTarget, exists := s.aliases[alias]; exists {
+		return []error{fmt.Errorf("alias %q already exists, pointing at %q", alias, existingTarget)}
+	}
+	if aliasChainCreatesCycle(s.aliases, alias, target) {
+		return []error{fmt.Errorf("adding alias %q -> %q would create an alias cycle", alias, target)}
+	}
+
+	s.aliases[alias] = target
 	return nil
 }
 
@@ -216,6 +308,10 @@ func (s *SimpleNameInterface) MissingDependencyError(depender string, dependerNa
 		)
 	}
 
+	if len(guess) == 0 {
+		guess = SuggestNames(dependency, depender, s.allKnownNames(), 3)
+	}
+
 	guessString := ""
 	if len(guess) > 0 {
 		guessString = fmt.Sprintf(" Did you mean %q?", guess)
@@ -223,10 +319,22 @@ func (s *SimpleNameInterface) MissingDependencyError(depender string, dependerNa
 	return fmt.Errorf("%q depends on undefined module %q.%s", depender, dependency, guessString)
 }
 
-func (s *SimpleNameInterface) GetNamespace(ctx NamespaceContext) Namespace {
-	return nil
+// allKnownNames returns the names of every live module, every skipped module, and
+// every alias, for use as candidates when suggesting "did you mean" corrections.
+func (s *SimpleNameInterface) allKnownNames() []string {
+	names := make([]string, 0, len(s.modules)+len(s.skippedModules)+len(s.aliases))
+	for name := range s.modules {
+		names = append(names, name)
+	}
+	for name := range s.skippedModules {
+		names = append(names, name)
+	}
+	for name := range s.aliases {
+		names = append(names, name)
+	}
+	return names
 }
 
-func (s *SimpleNameInterface) UniqueName(ctx NamespaceContext, name string) (unique string) {
-	return name
+func (s *SimpleNameInterface) GetNamespace(ctx NamespaceContext) Namespace {
+	return nil
 }
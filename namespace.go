@@ -0,0 +1,533 @@
+// Copyright 2017 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blueprint
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// NamespaceNameInterface is a NameInterface that partitions modules into a tree of
+// namespaces, one per declared directory, analogous to Soong's NameResolver. Every
+// directory under a declared namespace inherits it, and a directory may declare its
+// own namespace that imports other namespaces by path. Modules may be referred to
+// either by their bare name, which is resolved against the current namespace and its
+// imports, or by a fully-qualified name of the form "//path/to/ns:modname".
+type NamespaceNameInterface struct {
+	mutex sync.RWMutex
+
+	// namespacesByPath is keyed by the declaration path of the namespace ("" for the
+	// root namespace).
+	namespacesByPath map[string]*namespace
+	rootNamespace    *namespace
+
+	// ModuleFilter, if set, is consulted by NewModule to decide whether a module
+	// should be registered at all. Modules it rejects are recorded as skipped
+	// instead. See NewNamespaceNameInterfaceWithFilter.
+	ModuleFilter ModuleFilter
+}
+
+// NewNamespaceNameInterface creates a NamespaceNameInterface with a single root
+// namespace already declared at the root of the source tree.
+func NewNamespaceNameInterface() *NamespaceNameInterface {
+	root := &namespace{
+		modules:         make(map[string]ModuleGroup),
+		skippedModules:  make(map[string][]SkippedModuleInfo),
+		aliases:         make(map[string]string),
+		uniqueNames:     make(map[string]string),
+		ninjaBaseClaims: make(map[string]int),
+	}
+	return &NamespaceNameInterface{
+		namespacesByPath: map[string]*namespace{"": root},
+		rootNamespace:    root,
+	}
+}
+
+// NewNamespaceNameInterfaceWithFilter is like NewNamespaceNameInterface, but the
+// returned NamespaceNameInterface's NewModule routes any module whose path filter
+// rejects to NewSkippedModule instead of registering it, the same as
+// NewSimpleNameInterfaceWithFilter. This allows a SourceRootDirs-style filter to be
+// combined with namespaces, as in typical Soong usage.
+func NewNamespaceNameInterfaceWithFilter(filter ModuleFilter) *NamespaceNameInterface {
+	r := NewNamespaceNameInterface()
+	r.ModuleFilter = filter
+	return r
+}
+
+// namespace is the concrete Namespace implementation used by NamespaceNameInterface.
+type namespace struct {
+	NamespaceMarker
+
+	// path is the directory (relative to the root of the source tree) at which this
+	// namespace was declared. The root namespace has path == "".
+	path string
+
+	modules         map[string]ModuleGroup
+	skippedModules  map[string][]SkippedModuleInfo
+	aliases         map[string]string
+	uniqueNames     map[string]string
+	ninjaBaseClaims map[string]int
+
+	// imports lists the declaration paths of namespaces this namespace may resolve
+	// bare module names against, in declaration order.
+	imports []string
+}
+
+// lookup resolves name within this namespace only, following local alias chains, and
+// does not consult imports or the root namespace.
+func (n *namespace) lookup(name string) (ModuleGroup, bool) {
+	group, found := n.modules[resolveAliasChain(n.aliases, name)]
+	return group, found
+}
+
+// label returns the "//path" form used to identify a namespace in error messages.
+func (n *namespace) label() string {
+	return "//" + n.path
+}
+
+// qualify returns the canonical "//path:name" form of name within this namespace.
+func (n *namespace) qualify(name string) string {
+	return "//" + n.path + ":" + name
+}
+
+// NewNamespace declares a namespace rooted at path, which must not already have a
+// namespace declared at it. path is relative to the root of the source tree.
+func (r *NamespaceNameInterface) NewNamespace(path string) []error {
+	path = cleanNamespacePath(path)
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, present := r.namespacesByPath[path]; present {
+		return []error{fmt.Errorf("namespace %q is already declared", "//"+path)}
+	}
+
+	r.namespacesByPath[path] = &namespace{
+		path:            path,
+		modules:         make(map[string]ModuleGroup),
+		skippedModules:  make(map[string][]SkippedModuleInfo),
+		aliases:         make(map[string]string),
+		uniqueNames:     make(map[string]string),
+		ninjaBaseClaims: make(map[string]int),
+	}
+	return nil
+}
+
+// SetNamespaceImports records, in order, the namespaces (identified by their
+// declaration paths) that the namespace declared at path may resolve bare module
+// names against. Both path and every entry of imports must already have been
+// declared via NewNamespace. Returns an error if the new imports would introduce a
+// cycle; in that case the namespace's imports are left unchanged.
+func (r *NamespaceNameInterface) SetNamespaceImports(path string, imports []string) []error {
+	path = cleanNamespacePath(path)
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	ns, ok := r.namespacesByPath[path]
+	if !ok {
+		return []error{fmt.Errorf("namespace %q was never declared", "//"+path)}
+	}
+
+	cleanedImports := make([]string, 0, len(imports))
+	for _, imp := range imports {
+		imp = cleanNamespacePath(imp)
+		if _, ok := r.namespacesByPath[imp]; !ok {
+			return []error{fmt.Errorf("namespace %q imports undeclared namespace %q", "//"+path, "//"+imp)}
+		}
+		cleanedImports = append(cleanedImports, imp)
+	}
+
+	previousImports := ns.imports
+	ns.imports = cleanedImports
+	if cycle := r.findImportCycle(path); cycle != nil {
+		ns.imports = previousImports
+		return []error{fmt.Errorf("namespace import cycle detected: %s", strings.Join(cycle, " -> "))}
+	}
+	return nil
+}
+
+// findImportCycle returns the path of namespaces (as "//"-prefixed labels) forming a
+// cycle reachable from start's imports, or nil if there is no cycle.
+func (r *NamespaceNameInterface) findImportCycle(start string) []string {
+	var stack []string
+	var visit func(path string) []string
+	visit = func(path string) []string {
+		for i, seen := range stack {
+			if seen == path {
+				cycle := append(append([]string{}, stack[i:]...), path)
+				for i := range cycle {
+					cycle[i] = "//" + cycle[i]
+				}
+				return cycle
+			}
+		}
+		stack = append(stack, path)
+		defer func() { stack = stack[:len(stack)-1] }()
+		for _, imp := range r.namespacesByPath[path].imports {
+			if cycle := visit(imp); cycle != nil {
+				return cycle
+			}
+		}
+		return nil
+	}
+	return visit(start)
+}
+
+func (r *NamespaceNameInterface) GetNamespace(ctx NamespaceContext) Namespace {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	dir := filepath.Dir(ctx.ModulePath())
+	for {
+		if ns, ok := r.namespacesByPath[dir]; ok {
+			return ns
+		}
+		if dir == "." || dir == "/" {
+			return r.rootNamespace
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return r.rootNamespace
+		}
+		dir = parent
+	}
+}
+
+func (r *NamespaceNameInterface) NewModule(ctx NamespaceContext, group ModuleGroup, module Module) (Namespace, []error) {
+	ns := r.GetNamespace(ctx).(*namespace)
+	name := group.name
+
+	if r.ModuleFilter != nil && !r.ModuleFilter(ctx.ModulePath()) {
+		r.NewSkippedModule(ctx, name, SkippedModuleInfo{
+			filename: ctx.ModulePath(),
+			reason:   fmt.Sprintf("path %q not under any allowed source root", ctx.ModulePath()),
+		})
+		return nil, nil
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if existing, present := ns.modules[name]; present {
+		return nil, []error{
+			// seven characters at the start of the second line to align with the string "error: "
+			fmt.Errorf("module %q already defined in namespace %q\n"+
+				"       %s <-- previous definition here", name, ns.label(), existing.modules.firstModule().pos),
+		}
+	}
+	if target, present := ns.aliases[name]; present {
+		return nil, []error{fmt.Errorf("module %q already defined as an alias for %q in namespace %q", name, target, ns.label())}
+	}
+
+	ns.modules[name] = group
+	return ns, nil
+}
+
+func (r *NamespaceNameInterface) NewSkippedModule(ctx NamespaceContext, name string, info SkippedModuleInfo) {
+	if name == "" {
+		return
+	}
+	ns := r.GetNamespace(ctx).(*namespace)
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	ns.skippedModules[name] = append(ns.skippedModules[name], info)
+}
+
+// ModuleFromName resolves moduleName within namespace. If moduleName is qualified
+// ("//path/to/ns:modname") it is looked up directly in the named namespace. Otherwise
+// it is looked up in namespace itself, then in namespace's imports in declaration
+// order, then in the root namespace.
+func (r *NamespaceNameInterface) ModuleFromName(moduleName string, callerNamespace Namespace) (group ModuleGroup, found bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if nsPath, name, qualified := splitQualifiedName(moduleName); qualified {
+		ns, ok := r.namespacesByPath[nsPath]
+		if !ok {
+			return ModuleGroup{}, false
+		}
+		return ns.lookup(name)
+	}
+
+	ns, _ := callerNamespace.(*namespace)
+	if ns == nil {
+		ns = r.rootNamespace
+	}
+
+	if group, found = ns.lookup(moduleName); found {
+		return group, true
+	}
+
+	for _, importPath := range ns.imports {
+		imported, ok := r.namespacesByPath[importPath]
+		if !ok {
+			continue
+		}
+		if group, found = imported.lookup(moduleName); found {
+			return group, true
+		}
+	}
+
+	if ns != r.rootNamespace {
+		if group, found = r.rootNamespace.lookup(moduleName); found {
+			return group, true
+		}
+	}
+
+	return ModuleGroup{}, false
+}
+
+func (r *NamespaceNameInterface) SkippedModuleFromName(moduleName string, callerNamespace Namespace) (skipInfos []SkippedModuleInfo, skipped bool) {
+	ns, _ := callerNamespace.(*namespace)
+	if ns == nil {
+		ns = r.rootNamespace
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	skipInfos, skipped = ns.skippedModules[moduleName]
+	return skipInfos, skipped
+}
+
+// Rename renames oldName to newName within namespace, then leaves oldName behind as
+// an alias to newName so that dependencies declared against the pre-rename name keep
+// resolving.
+func (r *NamespaceNameInterface) Rename(oldName string, newName string, callerNamespace Namespace) (errs []error) {
+	ns, ok := callerNamespace.(*namespace)
+	if !ok || ns == nil {
+		return []error{fmt.Errorf("Rename called with a namespace that was not obtained from this NameInterface")}
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if existing, present := ns.modules[newName]; present {
+		return []error{
+			// seven characters at the start of the second line to align with the string "error: "
+			fmt.Errorf("renaming module %q to %q conflicts with existing module in namespace %q\n"+
+				"       %s <-- existing module defined here", oldName, newName, ns.label(), existing.modules.firstModule().pos),
+		}
+	}
+	if target, present := ns.aliases[newName]; present {
+		return []error{fmt.Errorf("renaming module %q to %q conflicts with existing alias to %q in namespace %q", oldName, newName, target, ns.label())}
+	}
+
+	group, exists := ns.modules[oldName]
+	if !exists {
+		return []error{fmt.Errorf("module %q to be renamed to %q doesn't exist in namespace %q", oldName, newName, ns.label())}
+	}
+	if aliasChainCreatesCycle(ns.aliases, oldName, newName) {
+		return []error{fmt.Errorf("renaming module %q to %q would create an alias cycle in namespace %q", oldName, newName, ns.label())}
+	}
+
+	delete(ns.modules, oldName)
+	group.name = newName
+	ns.modules[newName] = group
+	ns.aliases[oldName] = newName
+	return nil
+}
+
+// AddAlias makes alias resolve to target within namespace when passed to
+// ModuleFromName.
+func (r *NamespaceNameInterface) AddAlias(alias string, target string, callerNamespace Namespace) (errs []error) {
+	ns, ok := callerNamespace.(*namespace)
+	if !ok || ns == nil {
+		ns = r.rootNamespace
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if existing, present := ns.modules[alias]; present {
+		return []error{
+			fmt.Errorf("alias %q conflicts with existing module in namespace %q\n"+
+				"       %s <-- existing module defined here", alias, ns.label(), existing.modules.firstModule().pos),
+		}
+	}
+	if existingTarget, present := ns.aliases[alias]; present {
+		return []error{fmt.Errorf("alias %q already exists in namespace %q, pointing at %q", alias, ns.label(), existingTarget)}
+	}
+	if aliasChainCreatesCycle(ns.aliases, alias, target) {
+		return []error{fmt.Errorf("adding alias %q -> %q would create an alias cycle in namespace %q", alias, target, ns.label())}
+	}
+
+	ns.aliases[alias] = target
+	return nil
+}
+
+// AllModules returns every module in every namespace, in a deterministic order:
+// namespaces ordered by declaration path, and modules within a namespace ordered by
+// name.
+func (r *NamespaceNameInterface) AllModules() []ModuleGroup {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	nsPaths := make([]string, 0, len(r.namespacesByPath))
+	for path := range r.namespacesByPath {
+		nsPaths = append(nsPaths, path)
+	}
+	sort.Strings(nsPaths)
+
+	groups := make([]ModuleGroup, 0)
+	for _, path := range nsPaths {
+		ns := r.namespacesByPath[path]
+		names := make([]string, 0, len(ns.modules))
+		for name := range ns.modules {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			groups = append(groups, ns.modules[name])
+		}
+	}
+	return groups
+}
+
+func (r *NamespaceNameInterface) MissingDependencyError(depender string, dependerNamespace Namespace, dependency string, guess []string) error {
+	ns, _ := dependerNamespace.(*namespace)
+	if ns == nil {
+		ns = r.rootNamespace
+	}
+
+	searched := []string{ns.label()}
+	for _, importPath := range ns.imports {
+		searched = append(searched, "//"+importPath)
+	}
+	if ns != r.rootNamespace {
+		searched = append(searched, r.rootNamespace.label())
+	}
+
+	if skipInfos, skipped := r.SkippedModuleFromName(dependency, ns); skipped {
+		filesFound := make([]string, 0, len(skipInfos))
+		reasons := make([]string, 0, len(skipInfos))
+		for _, info := range skipInfos {
+			filesFound = append(filesFound, info.filename)
+			reasons = append(reasons, info.reason)
+		}
+		return fmt.Errorf(
+			"module %q depends on skipped module %q; %q was defined in files(s) [%v], but was skipped for reason(s) [%v]",
+			ns.qualify(depender),
+			dependency,
+			dependency,
+			strings.Join(filesFound, ", "),
+			strings.Join(reasons, "; "),
+		)
+	}
+
+	if len(guess) == 0 {
+		guess = SuggestNames(dependency, depender, r.namespaceKnownNames(ns), 3)
+	}
+
+	guessString := ""
+	if len(guess) > 0 {
+		guessString = fmt.Sprintf(" Did you mean %q?", guess)
+	}
+	return fmt.Errorf("%q depends on undefined module %q; searched namespaces [%s].%s",
+		ns.qualify(depender), dependency, strings.Join(searched, ", "), guessString)
+}
+
+// namespaceKnownNames returns the names of every module, skipped module, and alias
+// visible to ns, searched in the same order as ModuleFromName: ns itself, then ns's
+// imports in declaration order, then the root namespace. Used as the candidate pool
+// for "did you mean" suggestions when MissingDependencyError isn't given an explicit
+// guess.
+func (r *NamespaceNameInterface) namespaceKnownNames(ns *namespace) []string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	names := ns.allKnownNames(nil)
+	for _, importPath := range ns.imports {
+		if imported, ok := r.namespacesByPath[importPath]; ok {
+			names = imported.allKnownNames(names)
+		}
+	}
+	if ns != r.rootNamespace {
+		names = r.rootNamespace.allKnownNames(names)
+	}
+	return names
+}
+
+// allKnownNames appends the name of every module, skipped module, and alias in n to
+// names and returns the result.
+func (n *namespace) allKnownNames(names []string) []string {
+	for name := range n.modules {
+		names = append(names, name)
+	}
+	for name := range n.skippedModules {
+		names = append(names, name)
+	}
+	for name := range n.aliases {
+		names = append(names, name)
+	}
+	return names
+}
+
+// UniqueName returns a ninja-safe identifier for name within ctx's namespace: the
+// "//path:name" form of name is sanitized the same way sanitizeNinjaName does for
+// SimpleNameInterface, with a short numeric suffix (e.g. "__2") appended if that would
+// otherwise collide with the sanitized qualified form of a different module in any
+// namespace (e.g. "//a:b:c" and "//a/b:c"). The first name queried for a given
+// sanitized base keeps it unsuffixed; every later module that sanitizes to the same
+// base gets the next free suffix, so the assignment depends on query order rather
+// than on registration order, but the same (ctx, name) pair always returns the same
+// string once queried and two distinct modules can never end up sharing a result.
+func (r *NamespaceNameInterface) UniqueName(ctx NamespaceContext, name string) string {
+	ns := r.GetNamespace(ctx).(*namespace)
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return ns.uniqueName(name, r.rootNamespace.ninjaBaseClaims)
+}
+
+// uniqueName is the namespace-scoped implementation behind
+// NamespaceNameInterface.UniqueName; see its doc comment. claimsByBase is shared by
+// every namespace, so results are unique across namespaces.
+func (n *namespace) uniqueName(name string, claimsByBase map[string]int) string {
+	return assignNinjaName(n.uniqueNames, claimsByBase, name, sanitizeNinjaName(n.qualify(name)))
+}
+
+// cleanNamespacePath normalizes a namespace declaration or import path so that
+// equivalent paths (e.g. "foo/bar", "foo/bar/", "/foo/bar") map to the same key. The
+// root namespace is represented by "".
+func cleanNamespacePath(path string) string {
+	path = strings.Trim(path, "/")
+	if path == "" || path == "." {
+		return ""
+	}
+	return filepath.Clean(path)
+}
+
+// splitQualifiedName splits a fully-qualified module name of the form
+// "//path/to/ns:modname" into its namespace path and module name. qualified is false
+// if name does not use the qualified form, in which case name is returned unchanged.
+func splitQualifiedName(name string) (nsPath string, moduleName string, qualified bool) {
+	if !strings.HasPrefix(name, "//") {
+		return "", name, false
+	}
+	rest := name[2:]
+	idx := strings.LastIndex(rest, ":")
+	if idx < 0 {
+		return "", name, false
+	}
+	return rest[:idx], rest[idx+1:], true
+}
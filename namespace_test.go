@@ -0,0 +1,219 @@
+// Copyright 2017 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blueprint
+
+import (
+	"strings"
+	"testing"
+	"text/scanner"
+)
+
+// newTestModuleGroup returns a ModuleGroup usable as a NewModule/Rename argument in
+// tests, with name as both its module name and a stand-in source position.
+func newTestModuleGroup(name string) ModuleGroup {
+	return ModuleGroup{&moduleGroup{
+		name:    name,
+		modules: moduleInfoList{&moduleInfo{pos: scanner.Position{Filename: name + ".bp", Line: 1}}},
+	}}
+}
+
+// testCtx returns a NamespaceContext for a module declared in the given Blueprints
+// file, e.g. testCtx("a/b/Blueprints").
+func testCtx(path string) NamespaceContext {
+	return newNamespaceContextFromFilename(path)
+}
+
+func TestNamespaceNameInterface_CrossNamespaceDeps(t *testing.T) {
+	r := NewNamespaceNameInterface()
+	if errs := r.NewNamespace("a"); errs != nil {
+		t.Fatalf("NewNamespace(a): %v", errs)
+	}
+	if errs := r.NewNamespace("b"); errs != nil {
+		t.Fatalf("NewNamespace(b): %v", errs)
+	}
+
+	aNs, errs := r.NewModule(testCtx("a/Blueprints"), newTestModuleGroup("foo"), nil)
+	if len(errs) != 0 {
+		t.Fatalf("NewModule(a/foo): %v", errs)
+	}
+	bNs, errs := r.NewModule(testCtx("b/Blueprints"), newTestModuleGroup("foo"), nil)
+	if len(errs) != 0 {
+		t.Fatalf("NewModule(b/foo): %v", errs)
+	}
+
+	aGroup, found := r.ModuleFromName("foo", aNs)
+	if !found || aGroup.name != "foo" {
+		t.Fatalf("ModuleFromName(foo, a) = %v, %v; want a's foo", aGroup, found)
+	}
+	bGroup, found := r.ModuleFromName("foo", bNs)
+	if !found || bGroup.name != "foo" {
+		t.Fatalf("ModuleFromName(foo, b) = %v, %v; want b's foo", bGroup, found)
+	}
+	if aGroup.moduleGroup == bGroup.moduleGroup {
+		t.Fatalf("namespace a and b resolved \"foo\" to the same moduleGroup")
+	}
+}
+
+func TestNamespaceNameInterface_QualifiedVsUnqualified(t *testing.T) {
+	r := NewNamespaceNameInterface()
+	if errs := r.NewNamespace("ns1"); errs != nil {
+		t.Fatalf("NewNamespace(ns1): %v", errs)
+	}
+	if _, errs := r.NewModule(testCtx("ns1/Blueprints"), newTestModuleGroup("foo"), nil); len(errs) != 0 {
+		t.Fatalf("NewModule(ns1/foo): %v", errs)
+	}
+
+	rootNs := r.GetNamespace(testCtx("Blueprints"))
+
+	if _, found := r.ModuleFromName("//ns1:foo", rootNs); !found {
+		t.Errorf("qualified lookup //ns1:foo not found from root")
+	}
+	if _, found := r.ModuleFromName("foo", rootNs); found {
+		t.Errorf("unqualified lookup foo unexpectedly found ns1's foo from root without an import")
+	}
+
+	if errs := r.SetNamespaceImports("", []string{"ns1"}); errs != nil {
+		t.Fatalf("SetNamespaceImports(root, [ns1]): %v", errs)
+	}
+	if _, found := r.ModuleFromName("foo", rootNs); !found {
+		t.Errorf("unqualified lookup foo not found from root after importing ns1")
+	}
+}
+
+func TestNamespaceNameInterface_ImportCycle(t *testing.T) {
+	r := NewNamespaceNameInterface()
+	for _, path := range []string{"a", "b", "c"} {
+		if errs := r.NewNamespace(path); errs != nil {
+			t.Fatalf("NewNamespace(%s): %v", path, errs)
+		}
+	}
+
+	if errs := r.SetNamespaceImports("a", []string{"b"}); errs != nil {
+		t.Fatalf("SetNamespaceImports(a, [b]): %v", errs)
+	}
+	if errs := r.SetNamespaceImports("b", []string{"c"}); errs != nil {
+		t.Fatalf("SetNamespaceImports(b, [c]): %v", errs)
+	}
+	if errs := r.SetNamespaceImports("c", []string{"a"}); errs == nil {
+		t.Fatalf("SetNamespaceImports(c, [a]) succeeded; want a cycle error")
+	}
+
+	// The rejected edge must not have been committed.
+	cNs := r.namespacesByPath["c"]
+	if len(cNs.imports) != 0 {
+		t.Errorf("namespace c retained imports %v after a rejected cyclic import", cNs.imports)
+	}
+}
+
+func TestNamespaceNameInterface_RenameConflict(t *testing.T) {
+	r := NewNamespaceNameInterface()
+	if errs := r.NewNamespace("a"); errs != nil {
+		t.Fatalf("NewNamespace(a): %v", errs)
+	}
+
+	ns, errs := r.NewModule(testCtx("a/Blueprints"), newTestModuleGroup("foo"), nil)
+	if len(errs) != 0 {
+		t.Fatalf("NewModule(a/foo): %v", errs)
+	}
+	if _, errs := r.NewModule(testCtx("a/Blueprints"), newTestModuleGroup("bar"), nil); len(errs) != 0 {
+		t.Fatalf("NewModule(a/bar): %v", errs)
+	}
+
+	if errs := r.Rename("foo", "bar", ns); errs == nil {
+		t.Fatalf("Rename(foo, bar) succeeded; want a conflict error")
+	}
+	if _, found := r.ModuleFromName("foo", ns); !found {
+		t.Errorf("foo was removed despite the rejected rename")
+	}
+}
+
+func TestNamespaceNameInterface_UniqueName_Sanitizes(t *testing.T) {
+	r := NewNamespaceNameInterface()
+	if errs := r.NewNamespace("a/b"); errs != nil {
+		t.Fatalf("NewNamespace(a/b): %v", errs)
+	}
+
+	if _, errs := r.NewModule(testCtx("a/b/Blueprints"), newTestModuleGroup("c d"), nil); len(errs) != 0 {
+		t.Fatalf("NewModule(a/b/c d): %v", errs)
+	}
+
+	if got := r.UniqueName(testCtx("a/b/Blueprints"), "c d"); got != "__a_b_c_d" {
+		t.Errorf("UniqueName(c d) = %q, want __a_b_c_d", got)
+	}
+}
+
+func TestNamespaceNameInterface_UniqueName_CollisionSuffix(t *testing.T) {
+	r := NewNamespaceNameInterface()
+	if errs := r.NewNamespace("a"); errs != nil {
+		t.Fatalf("NewNamespace(a): %v", errs)
+	}
+
+	if _, errs := r.NewModule(testCtx("a/Blueprints"), newTestModuleGroup("b:c"), nil); len(errs) != 0 {
+		t.Fatalf("NewModule(a/b:c): %v", errs)
+	}
+	if _, errs := r.NewModule(testCtx("a/Blueprints"), newTestModuleGroup("b/c"), nil); len(errs) != 0 {
+		t.Fatalf("NewModule(a/b/c): %v", errs)
+	}
+
+	// Whichever name is queried first claims the unsuffixed base; the later query
+	// for a colliding name gets the next free suffix.
+	gotColon := r.UniqueName(testCtx("a/Blueprints"), "b:c")
+	gotSlash := r.UniqueName(testCtx("a/Blueprints"), "b/c")
+	want := "__a_b_c"
+	if gotColon != want {
+		t.Errorf("UniqueName(b:c) = %q, want %s (queried first)", gotColon, want)
+	}
+	if gotSlash != want+"__2" {
+		t.Errorf("UniqueName(b/c) = %q, want %s__2 (queried second)", gotSlash, want)
+	}
+}
+
+// TestNamespaceNameInterface_UniqueName_QueryOrderNeverCollides guards against the
+// regression where two colliding modules in the same namespace, queried in
+// non-alphabetical order relative to their registration, were both assigned the same
+// unsuffixed base.
+func TestNamespaceNameInterface_UniqueName_QueryOrderNeverCollides(t *testing.T) {
+	r := NewNamespaceNameInterface()
+	if errs := r.NewNamespace("a"); errs != nil {
+		t.Fatalf("NewNamespace(a): %v", errs)
+	}
+
+	if _, errs := r.NewModule(testCtx("a/Blueprints"), newTestModuleGroup("b:c"), nil); len(errs) != 0 {
+		t.Fatalf("NewModule(a/b:c): %v", errs)
+	}
+	first := r.UniqueName(testCtx("a/Blueprints"), "b:c")
+
+	if _, errs := r.NewModule(testCtx("a/Blueprints"), newTestModuleGroup("b/c"), nil); len(errs) != 0 {
+		t.Fatalf("NewModule(a/b/c): %v", errs)
+	}
+	second := r.UniqueName(testCtx("a/Blueprints"), "b/c")
+
+	if first == second {
+		t.Fatalf("UniqueName(b:c) and UniqueName(b/c) both returned %q; want distinct results", first)
+	}
+}
+
+func TestNamespaceNameInterface_MissingDependencyError_Suggests(t *testing.T) {
+	r := NewNamespaceNameInterface()
+	ns, errs := r.NewModule(testCtx("Blueprints"), newTestModuleGroup("foo-client"), nil)
+	if len(errs) != 0 {
+		t.Fatalf("NewModule(foo-client): %v", errs)
+	}
+
+	err := r.MissingDependencyError("depender", ns, "foo-clint", nil)
+	if !strings.Contains(err.Error(), `"foo-client"`) {
+		t.Errorf("MissingDependencyError() = %v, want it to suggest %q", err, "foo-client")
+	}
+}
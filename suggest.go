@@ -0,0 +1,151 @@
+// Copyright 2017 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blueprint
+
+import "sort"
+
+// SuggestNames ranks candidates by approximate similarity to query and returns up to
+// max of the closest matches, most similar first, for use in "did you mean" hints.
+// context is typically the name of the module that depends on query. When query
+// shares a prefix of at least 3 characters with context, candidates that share at
+// least as long a prefix with context have their distance reduced by one, which puts
+// them ahead of equally-distant candidates, since that's the common case of a
+// mistyped or renamed variant (e.g. a context of
+// "foo-client-ndk" mistakenly depending on "foo-V1-ndk" instead of "foo-V2-ndk").
+func SuggestNames(query string, context string, candidates []string, max int) []string {
+	type scored struct {
+		name     string
+		distance int
+	}
+
+	maxLenDiff := len(query) / 4
+	if maxLenDiff < 2 {
+		maxLenDiff = 2
+	}
+	maxDistance := len(query) / 3
+	if maxDistance > 3 {
+		maxDistance = 3
+	}
+
+	contextPrefixLen := commonPrefixLen(query, context)
+
+	var results []scored
+	for _, candidate := range candidates {
+		if candidate == query {
+			continue
+		}
+		if abs(len(candidate)-len(query)) > maxLenDiff {
+			continue
+		}
+		distance := damerauLevenshtein(query, candidate, maxDistance)
+		if distance < 0 {
+			continue
+		}
+		if contextPrefixLen >= 3 && commonPrefixLen(candidate, context) >= contextPrefixLen {
+			distance--
+		}
+		results = append(results, scored{candidate, distance})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].distance != results[j].distance {
+			return results[i].distance < results[j].distance
+		}
+		return results[i].name < results[j].name
+	})
+
+	if len(results) > max {
+		results = results[:max]
+	}
+
+	names := make([]string, len(results))
+	for i, r := range results {
+		names[i] = r.name
+	}
+	return names
+}
+
+// damerauLevenshtein computes the Damerau-Levenshtein edit distance (insertions,
+// deletions, substitutions, and transpositions of adjacent characters) between a and
+// b, keeping only the last two rows of the dynamic-programming table in memory. It
+// returns -1 as soon as it can prove the distance exceeds maxDistance, without
+// finishing the computation.
+func damerauLevenshtein(a, b string, maxDistance int) int {
+	ar, br := []rune(a), []rune(b)
+	if len(ar) < len(br) {
+		ar, br = br, ar
+	}
+	if len(ar)-len(br) > maxDistance {
+		return -1
+	}
+
+	twoAgo := make([]int, len(br)+1)
+	prev := make([]int, len(br)+1)
+	cur := make([]int, len(br)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ar); i++ {
+		cur[0] = i
+		rowMin := cur[0]
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+			best := prev[j] + 1 // deletion
+			if v := cur[j-1] + 1; v < best {
+				best = v // insertion
+			}
+			if v := prev[j-1] + cost; v < best {
+				best = v // substitution
+			}
+			if i > 1 && j > 1 && ar[i-1] == br[j-2] && ar[i-2] == br[j-1] {
+				if v := twoAgo[j-2] + 1; v < best {
+					best = v // transposition
+				}
+			}
+			cur[j] = best
+			if best < rowMin {
+				rowMin = best
+			}
+		}
+		if rowMin > maxDistance {
+			return -1
+		}
+		twoAgo, prev, cur = prev, cur, twoAgo
+	}
+
+	if prev[len(br)] > maxDistance {
+		return -1
+	}
+	return prev[len(br)]
+}
+
+func commonPrefixLen(a, b string) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,96 @@
+// Copyright 2017 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blueprint
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDamerauLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"foo", "foo", 0},
+		{"foo", "foo1", 1},
+		{"foo", "fo", 1},
+		{"kitten", "sitting", 3},
+		{"ab", "ba", 1}, // adjacent transposition
+	}
+	for _, tt := range tests {
+		if got := damerauLevenshtein(tt.a, tt.b, len(tt.a)+len(tt.b)); got != tt.want {
+			t.Errorf("damerauLevenshtein(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestDamerauLevenshtein_Cutoff(t *testing.T) {
+	if got := damerauLevenshtein("kitten", "sitting", 1); got != -1 {
+		t.Errorf("damerauLevenshtein(kitten, sitting, maxDistance=1) = %d, want -1", got)
+	}
+}
+
+func TestSuggestNames_RanksByDistance(t *testing.T) {
+	candidates := []string{"foo-client", "boo-client", "foo-clients", "unrelated"}
+	got := SuggestNames("foo-client", "somedepender", candidates, 3)
+	want := []string{"boo-client", "foo-clients"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SuggestNames = %v, want %v", got, want)
+	}
+}
+
+func TestSuggestNames_MaxLimit(t *testing.T) {
+	candidates := []string{"fool", "food", "fort", "foot"}
+	got := SuggestNames("foo", "depender", candidates, 2)
+	if len(got) != 2 {
+		t.Fatalf("SuggestNames returned %d results, want 2: %v", len(got), got)
+	}
+}
+
+func TestSuggestNames_ContextPrefixBonus(t *testing.T) {
+	// Both candidates are equally distance 1 from the query, and "boo-V2-ndk" would
+	// sort first alphabetically, but "foo-V1-ndk" shares a name prefix with the
+	// depender ("foo-client-ndk") and should be nudged ahead of it, matching the
+	// versioned-variant hints Soong emits.
+	candidates := []string{"foo-V1-ndk", "boo-V2-ndk"}
+	got := SuggestNames("foo-V2-ndk", "foo-client-ndk", candidates, 2)
+	if len(got) == 0 || got[0] != "foo-V1-ndk" {
+		t.Errorf("SuggestNames = %v, want %q ranked first due to shared prefix with depender", got, "foo-V1-ndk")
+	}
+}
+
+func TestSuggestNames_ExcludesExactMatch(t *testing.T) {
+	got := SuggestNames("foo", "depender", []string{"foo", "fooo"}, 3)
+	for _, name := range got {
+		if name == "foo" {
+			t.Errorf("SuggestNames included the exact query %q as a suggestion", name)
+		}
+	}
+}
+
+func TestSimpleNameInterface_MissingDependencyError_Suggests(t *testing.T) {
+	s := NewSimpleNameInterface()
+	if _, errs := s.NewModule(testCtx("Blueprints"), newTestModuleGroup("foo-client"), nil); len(errs) != 0 {
+		t.Fatalf("NewModule: %v", errs)
+	}
+
+	err := s.MissingDependencyError("depender", nil, "foo-clint", nil)
+	if err == nil || !strings.Contains(err.Error(), "foo-client") {
+		t.Errorf("MissingDependencyError() = %v, want it to suggest %q", err, "foo-client")
+	}
+}
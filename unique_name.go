@@ -0,0 +1,87 @@
+// Copyright 2017 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blueprint
+
+import (
+	"fmt"
+	"regexp"
+)
+
+var ninjaUnsafeChars = regexp.MustCompile(`[^A-Za-z0-9_.\-]`)
+
+// sanitizeNinjaName replaces every character outside [A-Za-z0-9_.-] with '_', so the
+// result is always safe to use as a ninja variable or file name.
+func sanitizeNinjaName(name string) string {
+	return ninjaUnsafeChars.ReplaceAllString(name, "_")
+}
+
+// NewSimpleNameInterfaceWithSanitize is like NewSimpleNameInterface, but the returned
+// SimpleNameInterface's UniqueName sanitizes names into ninja-safe identifiers
+// instead of returning them unchanged. This supports relaxing module naming
+// restrictions to allow names containing characters ninja can't, such as '/', ':', or
+// spaces, without breaking ninja variable emission.
+func NewSimpleNameInterfaceWithSanitize() *SimpleNameInterface {
+	s := NewSimpleNameInterface()
+	s.SanitizeUniqueNames = true
+	return s
+}
+
+// UniqueName returns name unchanged unless SanitizeUniqueNames is set, in which case
+// it returns a ninja-safe identifier for name: characters outside [A-Za-z0-9_.-] are
+// replaced with '_', and a short numeric suffix (e.g. "__2") is appended if that would
+// otherwise collide with the sanitized form of a different module name. The first name
+// queried for a given sanitized base keeps it unsuffixed; every later name that
+// sanitizes to the same base gets the next free suffix, so the assignment depends on
+// query order rather than on registration order, but the same (ctx, name) pair always
+// returns the same string once queried and two distinct names can never end up sharing
+// a result.
+//
+// SimpleNameInterface's GetNamespace always returns nil, so there is only ever one
+// namespace to scope against here; NamespaceNameInterface's UniqueName is the one
+// that scopes identifiers by namespace, since it's the implementation that actually
+// has more than one.
+func (s *SimpleNameInterface) UniqueName(ctx NamespaceContext, name string) (unique string) {
+	if !s.SanitizeUniqueNames {
+		return name
+	}
+
+	return assignNinjaName(s.uniqueNames, s.ninjaBaseClaims, name, sanitizeNinjaName(name))
+}
+
+// assignNinjaName returns the cached entry for name in uniqueNames if one already
+// exists. Otherwise it claims base for name: if nothing has claimed base yet, name
+// keeps it unsuffixed; otherwise name gets base plus a short numeric suffix (e.g.
+// "__2"), starting one past the number of names that already claimed base and
+// counting up past any suffixed form that has itself already been claimed. Every
+// result is recorded in claimsByBase and cached in uniqueNames, so two names can
+// never be assigned the same result regardless of the order they're queried in.
+func assignNinjaName(uniqueNames map[string]string, claimsByBase map[string]int, name string, base string) string {
+	if cached, ok := uniqueNames[name]; ok {
+		return cached
+	}
+
+	claims := claimsByBase[base]
+	unique := base
+	for n := claims + 1; claimsByBase[unique] > 0; n++ {
+		unique = fmt.Sprintf("%s__%d", base, n)
+	}
+	claimsByBase[base] = claims + 1
+	if unique != base {
+		claimsByBase[unique]++
+	}
+
+	uniqueNames[name] = unique
+	return unique
+}
@@ -0,0 +1,112 @@
+// Copyright 2017 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blueprint
+
+import "testing"
+
+func TestSanitizeNinjaName(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"foo", "foo"},
+		{"foo.bar-1_2", "foo.bar-1_2"},
+		{"a/b", "a_b"},
+		{"a:b", "a_b"},
+		{"a b", "a_b"},
+	}
+	for _, tt := range tests {
+		if got := sanitizeNinjaName(tt.in); got != tt.want {
+			t.Errorf("sanitizeNinjaName(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSimpleNameInterface_UniqueName_DefaultIsIdentity(t *testing.T) {
+	s := NewSimpleNameInterface()
+	if got := s.UniqueName(testCtx("Blueprints"), "a/b"); got != "a/b" {
+		t.Errorf("UniqueName(a/b) = %q, want unchanged (sanitizing not enabled)", got)
+	}
+}
+
+func TestSimpleNameInterface_UniqueName_Sanitizes(t *testing.T) {
+	s := NewSimpleNameInterfaceWithSanitize()
+	if got := s.UniqueName(testCtx("Blueprints"), "a/b:c d"); got != "a_b_c_d" {
+		t.Errorf("UniqueName(a/b:c d) = %q, want a_b_c_d", got)
+	}
+}
+
+func TestSimpleNameInterface_UniqueName_CollisionSuffix(t *testing.T) {
+	s := NewSimpleNameInterfaceWithSanitize()
+	if _, errs := s.NewModule(testCtx("Blueprints"), newTestModuleGroup("a/b"), nil); len(errs) != 0 {
+		t.Fatalf("NewModule(a/b): %v", errs)
+	}
+	if _, errs := s.NewModule(testCtx("Blueprints"), newTestModuleGroup("a:b"), nil); len(errs) != 0 {
+		t.Fatalf("NewModule(a:b): %v", errs)
+	}
+
+	// Query in the opposite order from registration, to make sure the assigned
+	// suffix depends on query order, not registration order: whichever name is
+	// queried first claims the unsuffixed base.
+	gotColon := s.UniqueName(testCtx("Blueprints"), "a:b")
+	gotSlash := s.UniqueName(testCtx("Blueprints"), "a/b")
+
+	if gotColon != "a_b" {
+		t.Errorf("UniqueName(a:b) = %q, want a_b (queried first)", gotColon)
+	}
+	if gotSlash != "a_b__2" {
+		t.Errorf("UniqueName(a/b) = %q, want a_b__2 (queried second)", gotSlash)
+	}
+}
+
+// TestSimpleNameInterface_UniqueName_QueryOrderNeverCollides guards against the
+// regression where two colliding names, queried in non-alphabetical order relative to
+// their registration, were both assigned the same unsuffixed base because the
+// suffix was derived by re-sorting all currently-registered colliding names instead
+// of being claimed live as each name is queried.
+func TestSimpleNameInterface_UniqueName_QueryOrderNeverCollides(t *testing.T) {
+	s := NewSimpleNameInterfaceWithSanitize()
+	if _, errs := s.NewModule(testCtx("Blueprints"), newTestModuleGroup("a:b"), nil); len(errs) != 0 {
+		t.Fatalf("NewModule(a:b): %v", errs)
+	}
+	first := s.UniqueName(testCtx("Blueprints"), "a:b")
+
+	if _, errs := s.NewModule(testCtx("Blueprints"), newTestModuleGroup("a/b"), nil); len(errs) != 0 {
+		t.Fatalf("NewModule(a/b): %v", errs)
+	}
+	second := s.UniqueName(testCtx("Blueprints"), "a/b")
+
+	if first == second {
+		t.Fatalf("UniqueName(a:b) and UniqueName(a/b) both returned %q; want distinct results", first)
+	}
+}
+
+func TestSimpleNameInterface_UniqueName_Persists(t *testing.T) {
+	s := NewSimpleNameInterfaceWithSanitize()
+	if _, errs := s.NewModule(testCtx("Blueprints"), newTestModuleGroup("a/b"), nil); len(errs) != 0 {
+		t.Fatalf("NewModule(a/b): %v", errs)
+	}
+
+	first := s.UniqueName(testCtx("Blueprints"), "a/b")
+	if first != "a_b" {
+		t.Fatalf("UniqueName(a/b) = %q, want a_b", first)
+	}
+
+	// A newly-introduced collision must not retroactively change an already-assigned
+	// unique name.
+	if _, errs := s.NewModule(testCtx("Blueprints"), newTestModuleGroup("a:b"), nil); len(errs) != 0 {
+		t.Fatalf("NewModule(a:b): %v", errs)
+	}
+	if again := s.UniqueName(testCtx("Blueprints"), "a/b"); again != first {
+		t.Errorf("UniqueName(a/b) changed from %q to %q after a later collision was introduced", first, again)
+	}
+}